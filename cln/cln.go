@@ -0,0 +1,222 @@
+// Package cln implements the peerswap lightning-node interface
+// (AddPaymentCallback, AddMessageHandler, SendMessage, GetPayreq, PayInvoice,
+// RebalancePayment, GetPeers, ...) against Core Lightning's gRPC service
+// (cln-grpc), as an alternative to the pkg/lnd backend.
+package cln
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sync"
+
+	cln_rpc "github.com/elementsproject/lightning/cln_rpc"
+	"github.com/sputn1ck/peerswap/lightning"
+	"github.com/sputn1ck/peerswap/messages"
+	"github.com/sputn1ck/peerswap/onchain"
+	"github.com/sputn1ck/peerswap/poll"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// defaultRiskFactor matches lnd's default pathfinding risk factor so that
+// manually built CLN routes behave comparably to lnd's SendPaymentV2.
+const defaultRiskFactor = 15
+
+type Cln struct {
+	client cln_rpc.NodeClient
+	cc     *grpc.ClientConn
+	ctx    context.Context
+
+	PollService    *poll.Service
+	bitcoinOnChain *onchain.BitcoinOnChain
+
+	messageHandler  []func(peerId string, msgType string, payload []byte) error
+	paymentCallback func(paymentLabel string)
+	pubkey          string
+
+	streamStatusMu sync.Mutex
+	streamStatus   map[string]*StreamStatus
+
+	payIndexPath string
+}
+
+func (c *Cln) DecodePayreq(payreq string) (paymentHash string, amountMsat uint64, err error) {
+	decoded, err := c.client.Decode(c.ctx, &cln_rpc.DecodeRequest{String_: payreq})
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(decoded.PaymentHash), decoded.AmountMsat.Msat, nil
+}
+
+func (c *Cln) PayInvoice(payreq string) (preimage string, err error) {
+	res, err := c.client.Pay(c.ctx, &cln_rpc.PayRequest{Bolt11: payreq})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(res.PaymentPreimage), nil
+}
+
+func (c *Cln) CheckChannel(shortChannelId string, amountSat uint64) (*cln_rpc.ListpeerchannelsChannels, error) {
+	res, err := c.client.ListPeerChannels(c.ctx, &cln_rpc.ListpeerchannelsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	var channel *cln_rpc.ListpeerchannelsChannels
+	for _, v := range res.Channels {
+		if v.ShortChannelId != nil && *v.ShortChannelId == shortChannelId {
+			channel = v
+			break
+		}
+	}
+	if channel == nil {
+		return nil, errors.New("channel not found")
+	}
+	if channel.SpendableMsat.Msat < amountSat*1000 {
+		return nil, errors.New("not enough outbound capacity to perform swapOut")
+	}
+
+	return channel, nil
+}
+
+func (c *Cln) GetPayreq(msatAmount uint64, preimageString string, label string, expiry uint64) (string, error) {
+	preimage, err := lightning.MakePreimageFromStr(preimageString)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := c.client.Invoice(c.ctx, &cln_rpc.InvoiceRequest{
+		AmountMsat: &cln_rpc.AmountOrAny{Value: &cln_rpc.AmountOrAny_Amount{Amount: &cln_rpc.Amount{Msat: msatAmount}}},
+		Label:      label,
+		Description: label,
+		Preimage:    preimage[:],
+		Expiry:      &expiry,
+		CltvExpiry:  144,
+	})
+	if err != nil {
+		return "", err
+	}
+	return res.Bolt11, nil
+}
+
+func (c *Cln) AddPaymentCallback(f func(paymentLabel string)) {
+	c.paymentCallback = f
+}
+
+func (c *Cln) SendMessage(peerId string, message []byte, messageType int) error {
+	peerBytes, err := hex.DecodeString(peerId)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("sending message %s %s %v", peerId, hex.EncodeToString(message), messageType)
+	_, err = c.client.SendCustomMsg(c.ctx, &cln_rpc.SendcustommsgRequest{
+		NodeId: peerBytes,
+		Msg:    encodeCustomMsg(uint16(messageType), message),
+	})
+	return err
+}
+
+func (c *Cln) AddMessageHandler(f func(peerId string, msgType string, payload []byte) error) {
+	c.messageHandler = append(c.messageHandler, f)
+}
+
+func (c *Cln) PrepareOpeningTransaction(address string, amount uint64) (txId string, txHex string, err error) {
+	return "", "", nil
+}
+
+func (c *Cln) GetPeers() []string {
+	res, err := c.client.ListPeers(c.ctx, &cln_rpc.ListpeersRequest{})
+	if err != nil {
+		log.Printf("could not listpeers: %v", err)
+		return nil
+	}
+
+	var peerlist []string
+	for _, peer := range res.Peers {
+		peerlist = append(peerlist, hex.EncodeToString(peer.Id))
+	}
+	return peerlist
+}
+
+func (c *Cln) StartListening() {
+	go c.superviseStream("invoices", c.listenInvoices)
+	go c.superviseStream("custommsg", c.listenMessages)
+}
+
+func (c *Cln) handleCustomMessage(peerId string, msgType uint16, payload []byte) error {
+	for _, v := range c.messageHandler {
+		if err := v(peerId, messages.MessageTypeToHexString(messages.MessageType(msgType)), payload); err != nil {
+			log.Printf("\n msghandler err: %v", err)
+		}
+	}
+	return nil
+}
+
+// NewCln dials Core Lightning's cln-grpc plugin using the same cert-based
+// mTLS layout the plugin documents: a shared ca.pem plus a client.pem /
+// client-key.pem pair, analogous to the TLS + macaroon setup the lnd
+// backend uses.
+func NewCln(ctx context.Context, address, caCertPath, clientCertPath, clientKeyPath, dataDir string, chain *onchain.BitcoinOnChain) (*Cln, error) {
+	cc, err := getClientConnection(ctx, address, caCertPath, clientCertPath, clientKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	client := cln_rpc.NewNodeClient(cc)
+
+	gi, err := client.Getinfo(ctx, &cln_rpc.GetinfoRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cln{
+		client:         client,
+		cc:             cc,
+		ctx:            ctx,
+		bitcoinOnChain: chain,
+		pubkey:         hex.EncodeToString(gi.Id),
+		streamStatus:   make(map[string]*StreamStatus),
+		payIndexPath:   filepath.Join(dataDir, "cln_pay_index.json"),
+	}, nil
+}
+
+func getClientConnection(ctx context.Context, address, caCertPath, clientCertPath, clientKeyPath string) (*grpc.ClientConn, error) {
+	caCert, err := ioutil.ReadFile(caCertPath)
+	if err != nil {
+		return nil, err
+	}
+	caPool := x509.NewCertPool()
+	if !caPool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("could not parse ca cert %s", caCertPath)
+	}
+
+	clientCert, err := tls.LoadX509KeyPair(clientCertPath, clientKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	creds := credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{clientCert},
+		RootCAs:      caPool,
+	})
+
+	maxMsgRecvSize := grpc.MaxCallRecvMsgSize(1 * 1024 * 1024 * 500)
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithBlock(),
+		grpc.WithDefaultCallOptions(maxMsgRecvSize),
+	}
+
+	conn, err := grpc.DialContext(ctx, address, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
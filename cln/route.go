@@ -0,0 +1,125 @@
+package cln
+
+import (
+	"encoding/hex"
+	"fmt"
+	"regexp"
+
+	cln_rpc "github.com/elementsproject/lightning/cln_rpc"
+)
+
+// maxRebalanceRetries bounds how many times RebalancePayment will rebuild a
+// route around a failing hop before giving up.
+const maxRebalanceRetries = 5
+
+// scidInErrorRe extracts a short channel id from a sendpay/waitsendpay
+// failure message, e.g. "WIRE_TEMPORARY_CHANNEL_FAILURE at 123x45x6".
+var scidInErrorRe = regexp.MustCompile(`(\d+x\d+x\d+)`)
+
+// RebalancePayment pays payreq out through the local channel identified by
+// channelId. CLN's getroute has no "outgoing channel" constraint like lnd's
+// OutgoingChanIds, so the route is built manually: a fixed first hop over
+// the chosen channel, followed by getroute from that peer to the
+// destination. Hops that fail are added to the exclude list and the route
+// is rebuilt, up to maxRebalanceRetries times.
+// maxFeePpm is accepted for interface parity with the lnd backend but isn't
+// enforced yet: CLN's sendpay/getroute have no fee-limit primitive like
+// lnd's FeeLimitMsat, unlike the outgoing-channel pinning above.
+func (c *Cln) RebalancePayment(payreq string, channelId string, maxFeePpm uint64) (preimage string, err error) {
+	decoded, err := c.client.Decode(c.ctx, &cln_rpc.DecodeRequest{String_: payreq})
+	if err != nil {
+		return "", err
+	}
+
+	channel, err := c.CheckChannel(channelId, decoded.AmountMsat.Msat/1000)
+	if err != nil {
+		return "", err
+	}
+
+	var exclude []string
+	for attempt := 0; attempt < maxRebalanceRetries; attempt++ {
+		route, err := c.buildRoute(decoded.AmountMsat.Msat, decoded.Payee, channel, exclude)
+		if err != nil {
+			return "", err
+		}
+
+		_, err = c.client.SendPay(c.ctx, &cln_rpc.SendpayRequest{
+			Route:       route,
+			PaymentHash: decoded.PaymentHash,
+			Bolt11:      &payreq,
+		})
+		if err != nil {
+			return "", err
+		}
+
+		res, err := c.client.WaitSendPay(c.ctx, &cln_rpc.WaitsendpayRequest{PaymentHash: decoded.PaymentHash})
+		if err != nil {
+			if scid := scidInErrorRe.FindString(err.Error()); scid != "" {
+				exclude = append(exclude, scid)
+				continue
+			}
+			return "", err
+		}
+		return hex.EncodeToString(res.PaymentPreimage), nil
+	}
+
+	return "", fmt.Errorf("rebalance payment failed after %d attempts, excluded %v", maxRebalanceRetries, exclude)
+}
+
+// buildRoute pins the first hop to channel (the outgoing channel the caller
+// requires) and asks CLN for the rest of the route from that peer to the
+// real invoice destination.
+func (c *Cln) buildRoute(amountMsat uint64, destination []byte, channel *cln_rpc.ListpeerchannelsChannels, exclude []string) ([]*cln_rpc.GetrouteRoute, error) {
+	rest, err := c.client.GetRoute(c.ctx, &cln_rpc.GetrouteRequest{
+		Id:         destination,
+		Fromid:     channel.PeerId,
+		AmountMsat: &cln_rpc.Amount{Msat: amountMsat},
+		Riskfactor: defaultRiskFactor,
+		Exclude:    exclude,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(rest.Route) == 0 {
+		return nil, fmt.Errorf("no route found via channel %s", *channel.ShortChannelId)
+	}
+
+	firstHopAmountMsat, firstHopDelay := firstHopAmountAndDelay(channel, rest.Route[0])
+	firstHop := &cln_rpc.GetrouteRoute{
+		Id:         channel.PeerId,
+		Channel:    *channel.ShortChannelId,
+		AmountMsat: &cln_rpc.Amount{Msat: firstHopAmountMsat},
+		Delay:      firstHopDelay,
+	}
+
+	return append([]*cln_rpc.GetrouteRoute{firstHop}, rest.Route...), nil
+}
+
+// firstHopAmountAndDelay derives what channel's peer must actually receive
+// (and the CLTV delta to give it) from next, the first hop getroute
+// returned. getroute treated Fromid (the peer) as the payment's origin, so
+// next.AmountMsat/Delay are what the peer forwards onward and don't yet
+// include the fee/expiry-delta the peer itself charges for relaying over
+// this same channel - add those back in from the peer's side of the
+// channel's policy.
+func firstHopAmountAndDelay(channel *cln_rpc.ListpeerchannelsChannels, next *cln_rpc.GetrouteRoute) (uint64, uint32) {
+	amountMsat := next.AmountMsat.Msat
+	delay := next.Delay
+
+	remote := channel.GetUpdates().GetRemote()
+	if remote == nil {
+		return amountMsat, delay
+	}
+
+	if remote.FeeBaseMsat != nil {
+		amountMsat += remote.FeeBaseMsat.Msat
+	}
+	if remote.FeeProportionalMillionths != nil {
+		amountMsat += next.AmountMsat.Msat * uint64(*remote.FeeProportionalMillionths) / 1_000_000
+	}
+	if remote.CltvExpiryDelta != nil {
+		delay += *remote.CltvExpiryDelta
+	}
+
+	return amountMsat, delay
+}
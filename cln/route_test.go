@@ -0,0 +1,54 @@
+package cln
+
+import (
+	"testing"
+
+	cln_rpc "github.com/elementsproject/lightning/cln_rpc"
+)
+
+func TestFirstHopAmountAndDelayAddsPeersOwnFee(t *testing.T) {
+	next := &cln_rpc.GetrouteRoute{
+		AmountMsat: &cln_rpc.Amount{Msat: 1_000_000},
+		Delay:      40,
+	}
+
+	feeBase := uint32(1_000)
+	feePpm := uint32(10_000) // 1%
+	cltvDelta := uint32(18)
+	channel := &cln_rpc.ListpeerchannelsChannels{
+		Updates: &cln_rpc.ListpeerchannelsChannelsUpdates{
+			Remote: &cln_rpc.ListpeerchannelsChannelsUpdatesRemote{
+				FeeBaseMsat:               &cln_rpc.Amount{Msat: uint64(feeBase)},
+				FeeProportionalMillionths: &feePpm,
+				CltvExpiryDelta:           &cltvDelta,
+			},
+		},
+	}
+
+	wantAmount := uint64(1_000_000) + uint64(feeBase) + uint64(1_000_000)*uint64(feePpm)/1_000_000
+	wantDelay := uint32(40) + cltvDelta
+
+	gotAmount, gotDelay := firstHopAmountAndDelay(channel, next)
+	if gotAmount != wantAmount {
+		t.Errorf("amount = %d, want %d", gotAmount, wantAmount)
+	}
+	if gotDelay != wantDelay {
+		t.Errorf("delay = %d, want %d", gotDelay, wantDelay)
+	}
+}
+
+func TestFirstHopAmountAndDelayWithoutPeerPolicy(t *testing.T) {
+	next := &cln_rpc.GetrouteRoute{
+		AmountMsat: &cln_rpc.Amount{Msat: 1_000_000},
+		Delay:      40,
+	}
+	channel := &cln_rpc.ListpeerchannelsChannels{}
+
+	gotAmount, gotDelay := firstHopAmountAndDelay(channel, next)
+	if gotAmount != 1_000_000 {
+		t.Errorf("amount = %d, want %d (no adjustment without a peer policy)", gotAmount, 1_000_000)
+	}
+	if gotDelay != 40 {
+		t.Errorf("delay = %d, want %d (no adjustment without a peer policy)", gotDelay, 40)
+	}
+}
@@ -0,0 +1,228 @@
+package cln
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+
+	cln_rpc "github.com/elementsproject/lightning/cln_rpc"
+)
+
+const (
+	reconnectMinBackoff = 500 * time.Millisecond
+	reconnectMaxBackoff = 60 * time.Second
+)
+
+// StreamStatus describes the current health of one of the long-running RPC
+// loops kept alive by Cln (invoice settlement polling, custom messages).
+type StreamStatus struct {
+	Name            string
+	Connected       bool
+	LastError       string
+	LastConnectedAt time.Time
+	ReconnectCount  int
+}
+
+func (c *Cln) StreamStatus() map[string]StreamStatus {
+	c.streamStatusMu.Lock()
+	defer c.streamStatusMu.Unlock()
+
+	out := make(map[string]StreamStatus, len(c.streamStatus))
+	for k, v := range c.streamStatus {
+		out[k] = *v
+	}
+	return out
+}
+
+func (c *Cln) setStreamConnected(name string) {
+	c.streamStatusMu.Lock()
+	defer c.streamStatusMu.Unlock()
+
+	s, ok := c.streamStatus[name]
+	if !ok {
+		s = &StreamStatus{Name: name}
+		c.streamStatus[name] = s
+	}
+	s.Connected = true
+	s.LastError = ""
+	s.LastConnectedAt = time.Now()
+}
+
+func (c *Cln) setStreamError(name string, err error) {
+	c.streamStatusMu.Lock()
+	defer c.streamStatusMu.Unlock()
+
+	s, ok := c.streamStatus[name]
+	if !ok {
+		s = &StreamStatus{Name: name}
+		c.streamStatus[name] = s
+	}
+	s.Connected = false
+	s.LastError = err.Error()
+	s.ReconnectCount++
+}
+
+// superviseStream reruns fn with exponential backoff and jitter whenever it
+// returns an error, until ctx is canceled.
+func (c *Cln) superviseStream(name string, fn func(ctx context.Context) error) {
+	backoff := reconnectMinBackoff
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		default:
+		}
+
+		err := fn(c.ctx)
+		if err == nil || c.ctx.Err() != nil {
+			return
+		}
+
+		c.setStreamError(name, err)
+		log.Printf("cln: %q loop stopped, retrying in %s: %v", name, backoff, err)
+
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-time.After(jitter(backoff)):
+		}
+
+		backoff *= 2
+		if backoff > reconnectMaxBackoff {
+			backoff = reconnectMaxBackoff
+		}
+	}
+}
+
+func jitter(d time.Duration) time.Duration {
+	spread := float64(d) * 0.2
+	return d + time.Duration(rand.Float64()*2*spread-spread)
+}
+
+// listenInvoices polls waitanyinvoice starting from the last persisted
+// pay index, so a restart or a reconnect never misses or replays a
+// settlement.
+func (c *Cln) listenInvoices(ctx context.Context) error {
+	lastPayIndex := c.loadPayIndex()
+	c.setStreamConnected("invoices")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		res, err := c.client.WaitAnyInvoice(ctx, &cln_rpc.WaitanyinvoiceRequest{LastpayIndex: &lastPayIndex})
+		if err != nil {
+			return err
+		}
+
+		if res.Status == cln_rpc.WaitanyinvoiceResponse_PAID {
+			c.paymentCallback(res.Label)
+		}
+		if res.PayIndex != nil {
+			lastPayIndex = *res.PayIndex
+			c.savePayIndex(lastPayIndex)
+		}
+	}
+}
+
+// listenMessages subscribes to incoming custom messages. On every
+// (re)connect it replays a "peer online" poll for all currently connected
+// peers, recovering from custom messages missed while disconnected.
+func (c *Cln) listenMessages(ctx context.Context) error {
+	client, err := c.client.SubscribeCustomMsg(ctx, &cln_rpc.StreamCustommsgRequest{})
+	if err != nil {
+		return err
+	}
+	c.setStreamConnected("custommsg")
+	c.replayPeerOnline()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+			msg, err := client.Recv()
+			if err != nil {
+				return err
+			}
+			msgType, payload := decodeCustomMsg(msg.Payload)
+			peerId := hex.EncodeToString(msg.PeerId)
+			if err := c.handleCustomMessage(peerId, msgType, payload); err != nil {
+				log.Printf("Error handling msg %v", err)
+			}
+		}
+	}
+}
+
+// replayPeerOnline polls every currently connected peer, used to recover
+// from custom messages that may have been missed while the stream was
+// disconnected.
+func (c *Cln) replayPeerOnline() {
+	if c.PollService == nil {
+		return
+	}
+	for _, peerId := range c.GetPeers() {
+		c.PollService.Poll(peerId)
+	}
+}
+
+// encodeCustomMsg/decodeCustomMsg prefix the TLV-style custom message
+// payload with a 2-byte big-endian message type, matching lnd's
+// SendCustomMessage/CustomMessage wire framing so both backends speak the
+// same on-the-wire format to peers.
+func encodeCustomMsg(msgType uint16, payload []byte) []byte {
+	buf := make([]byte, 2+len(payload))
+	binary.BigEndian.PutUint16(buf, msgType)
+	copy(buf[2:], payload)
+	return buf
+}
+
+func decodeCustomMsg(raw []byte) (uint16, []byte) {
+	if len(raw) < 2 {
+		return 0, nil
+	}
+	return binary.BigEndian.Uint16(raw[:2]), raw[2:]
+}
+
+func (c *Cln) loadPayIndex() uint64 {
+	if c.payIndexPath == "" {
+		return 0
+	}
+	data, err := ioutil.ReadFile(c.payIndexPath)
+	if err != nil {
+		return 0
+	}
+	var idx uint64
+	if err := json.Unmarshal(data, &idx); err != nil {
+		log.Printf("could not parse cln pay index checkpoint, starting fresh: %v", err)
+		return 0
+	}
+	return idx
+}
+
+func (c *Cln) savePayIndex(idx uint64) {
+	if c.payIndexPath == "" {
+		return
+	}
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(c.payIndexPath), 0755); err != nil {
+		log.Printf("could not create cln pay index checkpoint dir: %v", err)
+		return
+	}
+	if err := ioutil.WriteFile(c.payIndexPath, data, 0644); err != nil {
+		log.Printf("could not persist cln pay index checkpoint: %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package cln
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodeDecodeCustomMsgRoundTrip(t *testing.T) {
+	cases := []struct {
+		msgType uint16
+		payload []byte
+	}{
+		{msgType: 0, payload: nil},
+		{msgType: 42, payload: []byte("hello")},
+		{msgType: 0xffff, payload: bytes.Repeat([]byte{0xab}, 32)},
+	}
+
+	for _, c := range cases {
+		encoded := encodeCustomMsg(c.msgType, c.payload)
+
+		gotType, gotPayload := decodeCustomMsg(encoded)
+		if gotType != c.msgType {
+			t.Fatalf("msgType = %d, want %d", gotType, c.msgType)
+		}
+		if !bytes.Equal(gotPayload, c.payload) {
+			t.Fatalf("payload = %x, want %x", gotPayload, c.payload)
+		}
+	}
+}
+
+func TestDecodeCustomMsgTooShort(t *testing.T) {
+	gotType, gotPayload := decodeCustomMsg([]byte{0x01})
+	if gotType != 0 || gotPayload != nil {
+		t.Fatalf("decodeCustomMsg(short) = (%d, %x), want (0, nil)", gotType, gotPayload)
+	}
+}
@@ -0,0 +1,102 @@
+package lnd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lightninglabs/lndclient"
+	"github.com/lightningnetwork/lnd/invoices"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/lnwire"
+)
+
+// errHoldInvoicesUnsupported is returned by GetHoldInvoice when the
+// connected lnd build wasn't compiled with invoicesrpc.
+var errHoldInvoicesUnsupported = errors.New("connected lnd build does not support hold invoices (invoicesrpc not built in)")
+
+// GetHoldInvoice creates a hold invoice for paymentHash that only settles
+// once SettleHoldInvoice is called with the matching preimage, instead of
+// the moment the payer's HTLC arrives. This lets the swap state machine wait
+// for the on-chain claim transaction to confirm before releasing the
+// preimage, making swap-out settlement atomic.
+func (l *Lnd) GetHoldInvoice(paymentHash [32]byte, msatAmount uint64, label string, expiry, cltvDelta uint64) (string, error) {
+	if !l.holdInvoicesSupported {
+		return "", errHoldInvoicesUnsupported
+	}
+
+	hash, err := lntypes.MakeHash(paymentHash[:])
+	if err != nil {
+		return "", err
+	}
+
+	return l.invoicesClient.AddHoldInvoice(l.ctx, lndclient.HoldInvoiceRequest{
+		Hash:       hash,
+		Value:      lnwire.MilliSatoshi(msatAmount),
+		Memo:       label,
+		Expiry:     int64(expiry),
+		CltvExpiry: uint32(cltvDelta),
+	})
+}
+
+// SettleHoldInvoice releases preimage to lnd, finalizing the hold invoice
+// created by GetHoldInvoice. The swap state machine should only call this
+// once the on-chain claim transaction has confirmed.
+func (l *Lnd) SettleHoldInvoice(preimage [32]byte) error {
+	p, err := lntypes.MakePreimage(preimage[:])
+	if err != nil {
+		return err
+	}
+	return l.invoicesClient.SettleInvoice(l.ctx, p)
+}
+
+// CancelHoldInvoice cancels a pending hold invoice, used on swap timeout.
+func (l *Lnd) CancelHoldInvoice(hash [32]byte) error {
+	h, err := lntypes.MakeHash(hash[:])
+	if err != nil {
+		return err
+	}
+	return l.invoicesClient.CancelInvoice(l.ctx, h)
+}
+
+// WatchHoldInvoice supervises a single hold invoice's state and calls
+// onAccepted once it reaches ACCEPTED (the payer's HTLC has locked in but
+// the invoice hasn't settled). It returns immediately; the watch runs in its
+// own goroutine and stops once the invoice resolves (settled or canceled)
+// or l.ctx is canceled.
+func (l *Lnd) WatchHoldInvoice(paymentHash [32]byte, onAccepted func()) {
+	hash, err := lntypes.MakeHash(paymentHash[:])
+	if err != nil {
+		log.Errorf("invalid payment hash for hold invoice watch: %v", err)
+		return
+	}
+
+	name := fmt.Sprintf("holdinvoice-%s", hash.String())
+	go l.superviseStream(name, func(ctx context.Context) error {
+		return l.listenHoldInvoice(ctx, name, hash, onAccepted)
+	})
+}
+
+func (l *Lnd) listenHoldInvoice(ctx context.Context, streamName string, hash lntypes.Hash, onAccepted func()) error {
+	invoiceChan, errChan, err := l.invoicesClient.SubscribeSingleInvoice(ctx, hash)
+	if err != nil {
+		return err
+	}
+	l.setStreamConnected(streamName)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errChan:
+			return err
+		case inv := <-invoiceChan:
+			switch inv.State {
+			case invoices.ContractAccepted:
+				onAccepted()
+			case invoices.ContractSettled, invoices.ContractCanceled:
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package lnd
+
+import "testing"
+
+func TestGetHoldInvoiceRejectsWhenUnsupported(t *testing.T) {
+	l := &Lnd{holdInvoicesSupported: false}
+
+	_, err := l.GetHoldInvoice([32]byte{}, 1000, "label", 3600, 144)
+	if err != errHoldInvoicesUnsupported {
+		t.Fatalf("got error %v, want errHoldInvoicesUnsupported", err)
+	}
+}
@@ -1,77 +1,136 @@
 package lnd
 
 import (
-	"context"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"path/filepath"
+	"sync"
+
+	"context"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/lightninglabs/lndclient"
+	"github.com/lightningnetwork/lnd/invoices"
 	"github.com/lightningnetwork/lnd/lnrpc"
-	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
-	"github.com/lightningnetwork/lnd/lnrpc/walletrpc"
+	"github.com/lightningnetwork/lnd/lnrpc/invoicesrpc"
+	"github.com/lightningnetwork/lnd/lnrpc/verrpc"
+	"github.com/lightningnetwork/lnd/lntypes"
 	"github.com/lightningnetwork/lnd/lnwire"
-	"github.com/lightningnetwork/lnd/macaroons"
+	"github.com/lightningnetwork/lnd/routing/route"
 	"github.com/sputn1ck/peerswap/lightning"
 	"github.com/sputn1ck/peerswap/messages"
 	"github.com/sputn1ck/peerswap/onchain"
 	"github.com/sputn1ck/peerswap/poll"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
-	"gopkg.in/macaroon.v2"
-	"io/ioutil"
-	"log"
-	"time"
 )
 
+// minLndMajor/minLndMinor and requiredLndBuildTags gate startup on an LND
+// build that actually has the subservers peerswap needs, instead of letting
+// peerswap discover a missing subserver mid-swap.
+const (
+	minLndMajor = 0
+	minLndMinor = 15
+)
+
+// invoicesrpc (needed for hold invoices, see holdinvoice.go) is deliberately
+// left out: it's a capability peerswap degrades gracefully without, checked
+// at runtime via holdInvoicesSupported instead of gating startup on it.
+var requiredLndBuildTags = []string{"routerrpc", "walletrpc", "signrpc", "chainrpc"}
+
+// defaultMaxFeePPM is the routing fee RebalancePayment will accept when the
+// caller hasn't configured one explicitly, in parts-per-million.
+const defaultMaxFeePPM = 500
+
+// Lnd drives an lnd node through github.com/lightninglabs/lndclient. All
+// direct RPC clients are kept unexported behind lndclient's own interfaces so
+// that lndclient/lndclienttest mocks can stand in for a running lnd node in
+// unit tests.
 type Lnd struct {
-	lndClient    lnrpc.LightningClient
-	walletClient walletrpc.WalletKitClient
-	routerClient routerrpc.RouterClient
+	lightningClient lndclient.LightningClient
+	walletClient    lndclient.WalletKitClient
+	routerClient    lndclient.RouterClient
+	invoicesClient  lndclient.InvoicesClient
+	chainNotifier   lndclient.ChainNotifierClient
+	signerClient    lndclient.SignerClient
+
+	services *lndclient.GrpcLndServices
+
+	metrics *Metrics
 
 	PollService    *poll.Service
 	bitcoinOnChain *onchain.BitcoinOnChain
 
-	cc  *grpc.ClientConn
 	ctx context.Context
 
 	messageHandler  []func(peerId string, msgType string, payload []byte) error
 	paymentCallback func(paymentLabel string)
 	pubkey          string
+	pubkeyVertex    route.Vertex
+
+	// MaxFeePPM bounds the routing fee RebalancePayment is willing to pay,
+	// expressed in parts-per-million of the payment amount.
+	MaxFeePPM uint64
+
+	// holdInvoicesSupported reports whether the connected lnd build has
+	// invoicesrpc's hold-invoice RPCs available, checked once at startup.
+	holdInvoicesSupported bool
+
+	streamStatusMu sync.Mutex
+	streamStatus   map[string]*StreamStatus
+
+	invoiceIndexPath string
 }
 
 func (l *Lnd) DecodePayreq(payreq string) (paymentHash string, amountMsat uint64, err error) {
-	decoded, err := l.lndClient.DecodePayReq(l.ctx, &lnrpc.PayReqString{PayReq: payreq})
+	decoded, err := l.lightningClient.DecodePaymentRequest(l.ctx, payreq)
 	if err != nil {
 		return "", 0, err
 	}
-	return decoded.PaymentHash, uint64(decoded.NumMsat), nil
+	return decoded.Hash.String(), uint64(decoded.Value), nil
 }
 
 func (l *Lnd) PayInvoice(payreq string) (preImage string, err error) {
-	payres, err := l.lndClient.SendPaymentSync(l.ctx, &lnrpc.SendRequest{PaymentRequest: payreq})
+	statusChan, errChan, err := l.lightningClient.PayInvoice(l.ctx, payreq, 0, nil)
 	if err != nil {
-		return "", nil
+		return "", err
+	}
+	for {
+		select {
+		case <-l.ctx.Done():
+			return "", errors.New("context done")
+		case err := <-errChan:
+			return "", err
+		case status := <-statusChan:
+			switch status.State {
+			case lnrpc.Payment_SUCCEEDED:
+				return status.Preimage.String(), nil
+			case lnrpc.Payment_IN_FLIGHT:
+				log.Debugf("payment in flight for %s", payreq)
+			case lnrpc.Payment_FAILED:
+				return "", fmt.Errorf("payment failed: %s", status.FailureReason)
+			}
+		}
 	}
-	return hex.EncodeToString(payres.PaymentPreimage), nil
 }
 
-func (l *Lnd) CheckChannel(shortChannelId string, amountSat uint64) (*lnrpc.Channel, error) {
-	res, err := l.lndClient.ListChannels(l.ctx, &lnrpc.ListChannelsRequest{ActiveOnly: true})
+func (l *Lnd) CheckChannel(shortChannelId string, amountSat uint64) (*lndclient.ChannelInfo, error) {
+	channels, err := l.lightningClient.ListChannels(l.ctx, false, true)
 	if err != nil {
 		return nil, err
 	}
 
-	var channel *lnrpc.Channel
-	for _, v := range res.Channels {
-		channelShortId := lnwire.NewShortChanIDFromInt(v.ChanId)
+	var channel *lndclient.ChannelInfo
+	for i, v := range channels {
+		channelShortId := lnwire.NewShortChanIDFromInt(v.ChannelID)
 		if channelShortId.String() == shortChannelId || LndShortChannelIdToCLShortChannelId(channelShortId) == shortChannelId {
-			channel = v
+			channel = &channels[i]
 			break
 		}
 	}
 	if channel == nil {
 		return nil, errors.New("channel not found")
 	}
-	if channel.LocalBalance < int64(amountSat) {
+	if channel.LocalBalance < btcutil.Amount(amountSat) {
 		return nil, errors.New("not enough outbound capacity to perform swapOut")
 	}
 
@@ -83,80 +142,57 @@ func (l *Lnd) GetPayreq(msatAmount uint64, preimageString string, label string,
 	if err != nil {
 		return "", err
 	}
+	lntypesPreimage, err := lntypes.MakePreimage(preimage[:])
+	if err != nil {
+		return "", err
+	}
 
-	payreq, err := l.lndClient.AddInvoice(l.ctx, &lnrpc.Invoice{
-		ValueMsat:  int64(msatAmount),
+	_, payreq, err := l.lightningClient.AddInvoice(l.ctx, &invoicesrpc.AddInvoiceData{
+		Preimage:   &lntypesPreimage,
+		Value:      lnwire.MilliSatoshi(msatAmount),
 		Memo:       label,
-		RPreimage:  preimage[:],
 		Expiry:     int64(expiry),
 		CltvExpiry: 144,
 	})
 	if err != nil {
 		return "", err
 	}
-	return payreq.PaymentRequest, nil
+	return payreq, nil
 }
 
 func (l *Lnd) AddPaymentCallback(f func(paymentLabel string)) {
 	l.paymentCallback = f
 }
 
-func (l *Lnd) RebalancePayment(payreq string, channelId string) (preimage string, err error) {
-	decoded, err := l.lndClient.DecodePayReq(l.ctx, &lnrpc.PayReqString{PayReq: payreq})
-	if err != nil {
-		return "", err
-	}
-
-	channel, err := l.CheckChannel(channelId, uint64(decoded.NumSatoshis))
-	if err != nil {
-		return "", err
-	}
-
-	paymentStream, err := l.routerClient.SendPaymentV2(l.ctx, &routerrpc.SendPaymentRequest{
-		PaymentRequest:  payreq,
-		TimeoutSeconds:  30,
-		OutgoingChanIds: []uint64{channel.ChanId},
-		MaxParts:        30,
-	})
-	for {
-		select {
-		case <-l.ctx.Done():
-			return "", errors.New("context done")
-		default:
-			res, err := paymentStream.Recv()
-			if err != nil {
-				return "", err
-			}
-			switch res.Status {
-			case lnrpc.Payment_SUCCEEDED:
-				return res.PaymentPreimage, nil
-			case lnrpc.Payment_IN_FLIGHT:
-				log.Printf("payment in flight")
-			case lnrpc.Payment_FAILED:
-				return "", fmt.Errorf("payment failure %s", res.FailureReason)
-			default:
-				continue
-			}
-			time.Sleep(time.Millisecond * 10)
-		}
+// RebalancePayment is a thin wrapper around RebalancePaymentWithProbe, using
+// maxFeePpm if the caller set one or the node's configured MaxFeePPM
+// otherwise.
+func (l *Lnd) RebalancePayment(payreq string, channelId string, maxFeePpm uint64) (preimage string, err error) {
+	if maxFeePpm == 0 {
+		maxFeePpm = l.MaxFeePPM
 	}
+	return l.RebalancePaymentWithProbe(payreq, channelId, maxFeePpm)
 }
 
 func (l *Lnd) SendMessage(peerId string, message []byte, messageType int) error {
-	peerBytes, err := hex.DecodeString(peerId)
+	peer, err := lndclient.NewNodeFromString(peerId)
 	if err != nil {
 		return err
 	}
 
-	log.Printf("sending message %s %s %v", peerId, hex.EncodeToString(message), messageType)
-	_, err = l.lndClient.SendCustomMessage(l.ctx, &lnrpc.SendCustomMessageRequest{
-		Peer: peerBytes,
-		Type: uint32(messageType),
-		Data: message,
+	log.Debugf("sending custom message peer=%s type=%d payload=%s", peerId, messageType, hex.EncodeToString(message))
+	err = l.lightningClient.SendCustomMessage(l.ctx, lndclient.CustomMessage{
+		Peer:    peer,
+		MsgType: uint32(messageType),
+		Data:    message,
 	})
 	if err != nil {
 		return err
 	}
+
+	if l.metrics != nil {
+		l.metrics.CustomMessagesSent.WithLabelValues(messages.MessageTypeToHexString(messages.MessageType(messageType))).Inc()
+	}
 	return nil
 }
 
@@ -169,184 +205,207 @@ func (l *Lnd) PrepareOpeningTransaction(address string, amount uint64) (txId str
 }
 
 func (l *Lnd) StartListening() {
-
-	go func() {
-		err := l.listenMessages()
-		if err != nil {
-			log.Printf("error listening on messages %v", err)
-		}
-	}()
-	go func() {
-		err := l.listenPayments()
-		if err != nil {
-			log.Printf("error listening on payments %v", err)
-		}
-	}()
-	go func() {
-		err := l.listenPeerEvents()
-		if err != nil {
-			log.Printf("error listening on peer events %v", err)
-		}
-	}()
+	go l.superviseStream("messages", l.listenMessages)
+	go l.superviseStream("payments", l.listenPayments)
+	go l.superviseStream("peerevents", l.listenPeerEvents)
 }
 
 func (l *Lnd) GetPeers() []string {
-	res, err := l.lndClient.ListPeers(l.ctx, &lnrpc.ListPeersRequest{})
+	peers, err := l.lightningClient.ListPeers(l.ctx)
 	if err != nil {
-		log.Printf("could not listpeers: %v", err)
+		log.Errorf("could not list peers: %v", err)
 		return nil
 	}
 
-	var peerlist []string
-	for _, peer := range res.Peers {
-		peerlist = append(peerlist, peer.PubKey)
+	peerlist := make([]string, 0, len(peers))
+	for _, peer := range peers {
+		peerlist = append(peerlist, peer.Pubkey.String())
 	}
 	return peerlist
 }
 
-func (l *Lnd) listenPayments() error {
-	client, err := l.lndClient.SubscribeInvoices(l.ctx, &lnrpc.InvoiceSubscription{})
+// listenPayments subscribes to settled invoices starting from the last
+// persisted AddIndex/SettleIndex checkpoint, so a reconnect (or a daemon
+// restart) can never silently miss a settlement.
+func (l *Lnd) listenPayments(ctx context.Context) error {
+	cp := l.loadInvoiceCheckpoint()
+	invoiceChan, errChan, err := l.lightningClient.SubscribeInvoices(ctx, lndclient.InvoiceSubscriptionRequest{
+		AddIndex:    cp.AddIndex,
+		SettleIndex: cp.SettleIndex,
+	})
 	if err != nil {
 		return err
 	}
+	l.setStreamConnected("payments")
+
 	for {
 		select {
-		case <-l.ctx.Done():
-			return client.CloseSend()
-		default:
-			msg, err := client.Recv()
-			if err != nil {
-				return err
-			}
-			if msg.State == lnrpc.Invoice_SETTLED {
-				l.paymentCallback(msg.Memo)
+		case <-ctx.Done():
+			return nil
+		case err := <-errChan:
+			return err
+		case inv := <-invoiceChan:
+			if inv.State == invoices.ContractSettled {
+				log.Infof("invoice settled hash=%s memo=%s", inv.Hash, inv.Memo)
+				if l.metrics != nil {
+					l.metrics.InvoicesSettled.Inc()
+				}
+				l.paymentCallback(inv.Memo)
 			}
+			l.saveInvoiceCheckpoint(invoiceCheckpoint{
+				AddIndex:    inv.AddIndex,
+				SettleIndex: inv.SettleIndex,
+			})
 		}
 	}
 }
 
-func (l *Lnd) listenMessages() error {
-	client, err := l.lndClient.SubscribeCustomMessages(l.ctx, &lnrpc.SubscribeCustomMessagesRequest{})
+// listenMessages subscribes to custom peer messages. On every (re)connect it
+// replays a "peer online" poll for all currently connected peers, so that any
+// swap-protocol messages missed while the stream was down get recovered
+// through the usual poll exchange.
+func (l *Lnd) listenMessages(ctx context.Context) error {
+	msgChan, errChan, err := l.lightningClient.SubscribeCustomMessages(ctx)
 	if err != nil {
 		return err
 	}
+	l.setStreamConnected("messages")
+	l.replayPeerOnline()
+
 	for {
 		select {
-		case <-l.ctx.Done():
-			return client.CloseSend()
-		default:
-			msg, err := client.Recv()
-			if err != nil {
-				return err
-			}
-
-			err = l.handleCustomMessage(msg)
-			if err != nil {
-				log.Printf("Error handling msg %v", err)
+		case <-ctx.Done():
+			return nil
+		case err := <-errChan:
+			return err
+		case msg := <-msgChan:
+			if err := l.handleCustomMessage(msg); err != nil {
+				log.Errorf("error handling custom message from peer=%s: %v", msg.Peer, err)
 			}
 		}
 	}
 }
 
-func (l *Lnd) listenPeerEvents() error {
-	client, err := l.lndClient.SubscribePeerEvents(l.ctx, &lnrpc.PeerEventSubscription{})
+func (l *Lnd) listenPeerEvents(ctx context.Context) error {
+	eventChan, errChan, err := l.lightningClient.SubscribePeerEvents(ctx)
 	if err != nil {
 		return err
 	}
+	l.setStreamConnected("peerevents")
+
 	for {
 		select {
-		case <-l.ctx.Done():
-			return client.CloseSend()
-		default:
-			msg, err := client.Recv()
-			if err != nil {
-				return err
-			}
-			if msg.Type == lnrpc.PeerEvent_PEER_ONLINE {
+		case <-ctx.Done():
+			return nil
+		case err := <-errChan:
+			return err
+		case event := <-eventChan:
+			if event.Type == lnrpc.PeerEvent_PEER_ONLINE {
+				if l.metrics != nil {
+					l.metrics.PeerOnlineEvents.Inc()
+				}
 				if l.PollService != nil {
-					l.PollService.Poll(msg.PubKey)
+					l.PollService.Poll(event.PubKey.String())
 				}
 			}
 		}
 	}
 }
 
-func (l *Lnd) handleCustomMessage(msg *lnrpc.CustomMessage) error {
-	peerId := hex.EncodeToString(msg.Peer)
-	for _, v := range l.messageHandler {
-		err := v(peerId, messages.MessageTypeToHexString(messages.MessageType(msg.Type)), msg.Data)
-		if err != nil {
-			log.Printf("\n msghandler err: %v", err)
-		}
+// replayPeerOnline polls every currently connected peer, used to recover
+// from custom messages that may have been missed while the message stream
+// was disconnected.
+func (l *Lnd) replayPeerOnline() {
+	if l.PollService == nil {
+		return
+	}
+	for _, peerId := range l.GetPeers() {
+		l.PollService.Poll(peerId)
 	}
-	return nil
 }
 
-func NewLnd(ctx context.Context, tlsCertPath, macaroonPath, address string, chain *onchain.BitcoinOnChain) (*Lnd, error) {
-	cc, err := getClientConnection(ctx, tlsCertPath, macaroonPath, address)
-	if err != nil {
-		return nil, err
+func (l *Lnd) handleCustomMessage(msg lndclient.CustomMessage) error {
+	peerId := msg.Peer.String()
+	msgType := messages.MessageTypeToHexString(messages.MessageType(msg.MsgType))
+	if l.metrics != nil {
+		l.metrics.CustomMessagesReceived.WithLabelValues(msgType).Inc()
 	}
-	lndClient := lnrpc.NewLightningClient(cc)
-	walletClient := walletrpc.NewWalletKitClient(cc)
-	routerClient := routerrpc.NewRouterClient(cc)
 
-	gi, err := lndClient.GetInfo(ctx, &lnrpc.GetInfoRequest{})
-	if err != nil {
-		return nil, err
+	for _, v := range l.messageHandler {
+		if err := v(peerId, msgType, msg.Data); err != nil {
+			log.Errorf("message handler error peer=%s type=%s: %v", peerId, msgType, err)
+		}
 	}
-	return &Lnd{
-		lndClient:      lndClient,
-		walletClient:   walletClient,
-		routerClient:   routerClient,
-		bitcoinOnChain: chain,
-		cc:             cc,
-		ctx:            ctx,
-		pubkey:         gi.IdentityPubkey,
-	}, nil
+	return nil
 }
 
-func getClientConnection(ctx context.Context, tlsCertPath, macaroonPath, address string) (*grpc.ClientConn, error) {
-	maxMsgRecvSize := grpc.MaxCallRecvMsgSize(1 * 1024 * 1024 * 500)
-
-	creds, err := credentials.NewClientTLSFromFile(tlsCertPath, "")
+// NewLnd connects to lnd via lndclient, using a macaroon pouch from
+// macaroonDir (which may hold either admin.macaroon or the individual
+// per-service macaroons) rather than a single hand-picked macaroon file.
+// Startup fails fast if the target lnd build is missing a required
+// subserver, instead of peerswap discovering that mid-swap.
+func NewLnd(ctx context.Context, lndAddress, network, tlsCertPath, macaroonDir, dataDir string, chain *onchain.BitcoinOnChain, metrics *Metrics) (*Lnd, error) {
+	services, err := lndclient.NewLndServices(&lndclient.LndServicesConfig{
+		LndAddress:  lndAddress,
+		Network:     lndclient.Network(network),
+		MacaroonDir: macaroonDir,
+		TLSPath:     tlsCertPath,
+		CheckVersion: &verrpc.Version{
+			AppMajor:  minLndMajor,
+			AppMinor:  minLndMinor,
+			BuildTags: requiredLndBuildTags,
+		},
+	})
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("connecting to lnd: %w", err)
 	}
 
-	macBytes, err := ioutil.ReadFile(macaroonPath)
+	info, err := services.Client.GetInfo(ctx)
 	if err != nil {
-		return nil, err
-	}
-
-	mac := &macaroon.Macaroon{}
-	if err := mac.UnmarshalBinary(macBytes); err != nil {
-		return nil, err
+		services.Close()
+		return nil, fmt.Errorf("getting lnd info: %w", err)
 	}
 
-	cred, err := macaroons.NewMacaroonCredential(mac)
-	if err != nil {
-		return nil, err
-	}
+	return &Lnd{
+		lightningClient:       services.Client,
+		walletClient:          services.WalletKit,
+		routerClient:          services.Router,
+		invoicesClient:        services.Invoices,
+		chainNotifier:         services.ChainNotifier,
+		signerClient:          services.Signer,
+		services:              services,
+		metrics:               metrics,
+		holdInvoicesSupported: hasBuildTag(services.Version, "invoicesrpc"),
+		bitcoinOnChain:        chain,
+		ctx:                   ctx,
+		pubkey:                info.IdentityPubkey.String(),
+		pubkeyVertex:          info.IdentityPubkey,
+		MaxFeePPM:             defaultMaxFeePPM,
+		streamStatus:          make(map[string]*StreamStatus),
+		invoiceIndexPath:      filepath.Join(dataDir, "invoice_checkpoint.json"),
+	}, nil
+}
 
-	if err := mac.UnmarshalBinary(macBytes); err != nil {
-		return nil, err
-	}
+// Stop tears down the underlying lndclient connection and must be called
+// once the supervising stream goroutines have exited.
+func (l *Lnd) Stop() {
+	l.services.Close()
+}
 
-	opts := []grpc.DialOption{
-		grpc.WithTransportCredentials(creds),
-		grpc.WithBlock(),
-		grpc.WithPerRPCCredentials(cred),
-		grpc.WithDefaultCallOptions(maxMsgRecvSize),
+// hasBuildTag reports whether the connected lnd's version handshake
+// advertised tag as one of its build tags.
+func hasBuildTag(v *verrpc.Version, tag string) bool {
+	if v == nil {
+		return false
 	}
-	conn, err := grpc.DialContext(ctx, address, opts...)
-	if err != nil {
-		return nil, err
+	for _, t := range v.BuildTags {
+		if t == tag {
+			return true
+		}
 	}
-	return conn, nil
-
+	return false
 }
 
 func LndShortChannelIdToCLShortChannelId(lndCI lnwire.ShortChannelID) string {
 	return fmt.Sprintf("%dx%dx%d", lndCI.BlockHeight, lndCI.TxIndex, lndCI.TxPosition)
-}
\ No newline at end of file
+}
@@ -0,0 +1,18 @@
+package lnd
+
+import "github.com/btcsuite/btclog"
+
+// Subsystem is this package's logging subsystem tag, following the same
+// per-package logger convention lnd itself uses.
+const Subsystem = "PSLN"
+
+// log is the package-wide logger. It does nothing until the daemon wires a
+// real one in via UseLogger, matching how lnd's own subsystems are set up.
+var log = btclog.Disabled
+
+// UseLogger sets the logger used by this package, letting the daemon route
+// it through the same log file/level/rotation config as the rest of
+// peerswap and lnd.
+func UseLogger(logger btclog.Logger) {
+	log = logger
+}
@@ -0,0 +1,101 @@
+package lnd
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics holds the Prometheus collectors for the lnd adapter: custom
+// messages, invoice settlements, rebalance attempts and stream health. It is
+// created once with NewMetrics and injected into NewLnd, so callers can
+// either register it with prometheus' default registry or keep it scoped to
+// a private one in tests.
+type Metrics struct {
+	CustomMessagesSent     *prometheus.CounterVec
+	CustomMessagesReceived *prometheus.CounterVec
+	InvoicesSettled        prometheus.Counter
+
+	RebalanceAttempts prometheus.Counter
+	RebalanceSuccess  prometheus.Counter
+	RebalanceFailure  prometheus.Counter
+	RebalanceFeeMsat  prometheus.Histogram
+
+	StreamReconnects *prometheus.CounterVec
+	PeerOnlineEvents prometheus.Counter
+}
+
+// NewMetrics registers the lnd adapter's collectors with reg and returns the
+// resulting Metrics. Pass prometheus.DefaultRegisterer for normal daemon
+// startup, or a fresh prometheus.NewRegistry() to keep collectors isolated
+// in tests.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	factory := promauto.With(reg)
+
+	return &Metrics{
+		CustomMessagesSent: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "peerswap",
+			Subsystem: "lnd",
+			Name:      "custom_messages_sent_total",
+			Help:      "Custom messages sent to peers, by message type.",
+		}, []string{"type"}),
+		CustomMessagesReceived: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "peerswap",
+			Subsystem: "lnd",
+			Name:      "custom_messages_received_total",
+			Help:      "Custom messages received from peers, by message type.",
+		}, []string{"type"}),
+		InvoicesSettled: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: "peerswap",
+			Subsystem: "lnd",
+			Name:      "invoices_settled_total",
+			Help:      "Invoices observed transitioning to the settled state.",
+		}),
+		RebalanceAttempts: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: "peerswap",
+			Subsystem: "lnd",
+			Name:      "rebalance_attempts_total",
+			Help:      "RebalancePayment calls started.",
+		}),
+		RebalanceSuccess: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: "peerswap",
+			Subsystem: "lnd",
+			Name:      "rebalance_success_total",
+			Help:      "RebalancePayment calls that succeeded.",
+		}),
+		RebalanceFailure: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: "peerswap",
+			Subsystem: "lnd",
+			Name:      "rebalance_failure_total",
+			Help:      "RebalancePayment calls that failed.",
+		}),
+		RebalanceFeeMsat: factory.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "peerswap",
+			Subsystem: "lnd",
+			Name:      "rebalance_fee_msat",
+			Help:      "Routing fee paid by successful rebalance payments, in msat.",
+			Buckets:   prometheus.ExponentialBuckets(100, 4, 10),
+		}),
+		StreamReconnects: factory.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "peerswap",
+			Subsystem: "lnd",
+			Name:      "stream_reconnects_total",
+			Help:      "Reconnect attempts for a supervised subscription stream, by stream name.",
+		}, []string{"stream"}),
+		PeerOnlineEvents: factory.NewCounter(prometheus.CounterOpts{
+			Namespace: "peerswap",
+			Subsystem: "lnd",
+			Name:      "peer_online_events_total",
+			Help:      "Peer-online events processed from the peer events stream.",
+		}),
+	}
+}
+
+// MetricsHandler returns an http.Handler serving the metrics registered
+// against reg in the Prometheus text exposition format, for the daemon to
+// mount at e.g. /metrics.
+func MetricsHandler(reg *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
+}
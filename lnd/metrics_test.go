@@ -0,0 +1,51 @@
+package lnd
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestNewMetricsRegistersAllCollectors(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewMetrics(reg)
+
+	// CounterVecs only emit a metric family once a label combination has
+	// been observed, so touch one before gathering.
+	m.CustomMessagesSent.WithLabelValues("test").Inc()
+	m.CustomMessagesReceived.WithLabelValues("test").Inc()
+	m.StreamReconnects.WithLabelValues("test").Inc()
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gathering metrics: %v", err)
+	}
+
+	want := map[string]bool{
+		"peerswap_lnd_custom_messages_sent_total":     false,
+		"peerswap_lnd_custom_messages_received_total": false,
+		"peerswap_lnd_invoices_settled_total":         false,
+		"peerswap_lnd_rebalance_attempts_total":       false,
+		"peerswap_lnd_rebalance_success_total":        false,
+		"peerswap_lnd_rebalance_failure_total":        false,
+		"peerswap_lnd_rebalance_fee_msat":             false,
+		"peerswap_lnd_stream_reconnects_total":        false,
+		"peerswap_lnd_peer_online_events_total":       false,
+	}
+
+	for _, mf := range mfs {
+		if _, ok := want[mf.GetName()]; ok {
+			want[mf.GetName()] = true
+		}
+	}
+
+	for name, found := range want {
+		if !found {
+			t.Errorf("metric %s was not registered", name)
+		}
+	}
+
+	// Touching a counter should not panic now that it's backed by a real
+	// (isolated) registry rather than the process-wide default one.
+	m.RebalanceAttempts.Inc()
+}
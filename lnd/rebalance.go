@@ -0,0 +1,146 @@
+package lnd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lightninglabs/lndclient"
+	"github.com/lightningnetwork/lnd/lnrpc"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/routing/route"
+)
+
+// minProbeShardMsat is the floor the pre-payment probe will not split
+// below when looking for the largest single-part route that succeeds.
+const (
+	minProbeShardMsat = 10_000_000 // 10k sat
+	probeTimeout      = 10 * time.Second
+)
+
+// RebalancePaymentWithProbe rebalances payreq out through channelId the same
+// way RebalancePayment does, but first probes the route to find the largest
+// part size that actually succeeds, and uses it to size MaxShardSizeMsat and
+// MaxParts on the real payment instead of the fixed MaxParts: 30 peerswap
+// used to hand SendPaymentV2 unconditionally. FeeLimitMsat is derived from
+// maxFeePpm. Hops that fail the probe are reported to lnd's mission control
+// so later retries - by this payment or any other - skip them.
+func (l *Lnd) RebalancePaymentWithProbe(payreq string, channelId string, maxFeePpm uint64) (preimage string, err error) {
+	if l.metrics != nil {
+		l.metrics.RebalanceAttempts.Inc()
+	}
+	defer func() {
+		if l.metrics == nil {
+			return
+		}
+		if err != nil {
+			l.metrics.RebalanceFailure.Inc()
+		} else {
+			l.metrics.RebalanceSuccess.Inc()
+		}
+	}()
+
+	decoded, err := l.lightningClient.DecodePaymentRequest(l.ctx, payreq)
+	if err != nil {
+		return "", err
+	}
+
+	channel, err := l.CheckChannel(channelId, uint64(decoded.Value.ToSatoshis()))
+	if err != nil {
+		return "", err
+	}
+
+	shardSizeMsat, err := l.probeMaxShardSize(uint64(decoded.Value), decoded.Destination, channel)
+	if err != nil {
+		return "", fmt.Errorf("probing route via channel %s: %w", channelId, err)
+	}
+
+	feeLimitMsat := uint64(decoded.Value) * maxFeePpm / 1_000_000
+	maxParts := uint64(decoded.Value)/shardSizeMsat + 1
+
+	statusChan, errChan, err := l.routerClient.SendPayment(l.ctx, lndclient.SendPaymentRequest{
+		Invoice:          payreq,
+		Timeout:          30 * time.Second,
+		MaxParts:         uint32(maxParts),
+		MaxShardSizeMsat: lnwire.MilliSatoshi(shardSizeMsat),
+		FeeLimitMsat:     lnwire.MilliSatoshi(feeLimitMsat),
+		OutgoingChanIds:  []uint64{channel.ChannelID},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		select {
+		case <-l.ctx.Done():
+			return "", errors.New("context done")
+		case err := <-errChan:
+			return "", err
+		case status := <-statusChan:
+			switch status.State {
+			case lnrpc.Payment_SUCCEEDED:
+				if l.metrics != nil {
+					l.metrics.RebalanceFeeMsat.Observe(float64(status.Fee))
+				}
+				return status.Preimage.String(), nil
+			case lnrpc.Payment_IN_FLIGHT:
+				log.Debugf("rebalance payment in flight, hash=%s", decoded.Hash)
+			case lnrpc.Payment_FAILED:
+				return "", fmt.Errorf("payment failure %s", status.FailureReason)
+			}
+		}
+	}
+}
+
+// probeMaxShardSize queries a route to destination constrained to channel's
+// outgoing channel, halving the amount on failure down to minProbeShardMsat,
+// and returns the largest amount that routed successfully. If amountMsat
+// itself is below minProbeShardMsat, it is still probed once instead of
+// being skipped outright.
+func (l *Lnd) probeMaxShardSize(amountMsat uint64, destination route.Vertex, channel *lndclient.ChannelInfo) (uint64, error) {
+	floor := uint64(minProbeShardMsat)
+	if amountMsat < floor {
+		floor = amountMsat
+	}
+
+	for amount := amountMsat; ; amount /= 2 {
+		ctx, cancel := context.WithTimeout(l.ctx, probeTimeout)
+		_, err := l.lightningClient.QueryRoutes(ctx, lndclient.QueryRoutesRequest{
+			Destination:     destination,
+			AmountMsat:      lnwire.MilliSatoshi(amount),
+			OutgoingChanIds: []uint64{channel.ChannelID},
+		})
+		cancel()
+		if err == nil {
+			return amount, nil
+		}
+
+		l.reportFailedHop(destination, amount, err)
+		if amount <= floor || amount/2 < floor {
+			break
+		}
+	}
+
+	return 0, fmt.Errorf("no route succeeds even at the minimum probe size (%d msat)", floor)
+}
+
+// reportFailedHop feeds a probe failure into lnd's mission control as the
+// edge from our node to destination, so subsequent route lookups - by this
+// retry or any other payment - avoid it for a while instead of re-probing it
+// from scratch.
+func (l *Lnd) reportFailedHop(destination route.Vertex, amountMsat uint64, probeErr error) {
+	log.Debugf("route probe for %d msat failed, updating mission control: %v", amountMsat, probeErr)
+
+	err := l.routerClient.XImportMissionControl(l.ctx, []lndclient.MissionControlEntry{
+		{
+			NodeFrom:   l.pubkeyVertex,
+			NodeTo:     destination,
+			AmountMsat: lnwire.MilliSatoshi(amountMsat),
+			Timestamp:  time.Now(),
+		},
+	})
+	if err != nil {
+		log.Errorf("could not update mission control: %v", err)
+	}
+}
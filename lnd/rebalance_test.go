@@ -0,0 +1,149 @@
+package lnd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lightninglabs/lndclient"
+	"github.com/lightningnetwork/lnd/routing/route"
+)
+
+// fakeLightningClient overrides just the LightningClient methods
+// probeMaxShardSize needs, leaving everything else to panic on the nil
+// embedded interface if ever called.
+type fakeLightningClient struct {
+	lndclient.LightningClient
+
+	queryRoutes func(amountMsat uint64) error
+}
+
+func (f *fakeLightningClient) QueryRoutes(ctx context.Context, req lndclient.QueryRoutesRequest) (*lndclient.QueryRoutesResponse, error) {
+	if err := f.queryRoutes(uint64(req.AmountMsat)); err != nil {
+		return nil, err
+	}
+	return &lndclient.QueryRoutesResponse{}, nil
+}
+
+// fakeRouterClient overrides just XImportMissionControl, used by
+// reportFailedHop on every probe failure.
+type fakeRouterClient struct {
+	lndclient.RouterClient
+
+	reported int
+}
+
+func (f *fakeRouterClient) XImportMissionControl(ctx context.Context, entries []lndclient.MissionControlEntry) error {
+	f.reported++
+	return nil
+}
+
+func newProbeTestLnd(queryRoutes func(amountMsat uint64) error) (*Lnd, *fakeRouterClient) {
+	router := &fakeRouterClient{}
+	return &Lnd{
+		ctx:             context.Background(),
+		lightningClient: &fakeLightningClient{queryRoutes: queryRoutes},
+		routerClient:    router,
+		pubkeyVertex:    route.Vertex{},
+	}, router
+}
+
+func TestProbeMaxShardSizeHalvesUntilSuccess(t *testing.T) {
+	const amountMsat = 80_000_000
+	const succeedsAt = 20_000_000
+
+	l, router := newProbeTestLnd(func(amount uint64) error {
+		if amount <= succeedsAt {
+			return nil
+		}
+		return errors.New("no route")
+	})
+
+	channel := &lndclient.ChannelInfo{ChannelID: 1}
+	got, err := l.probeMaxShardSize(amountMsat, route.Vertex{}, channel)
+	if err != nil {
+		t.Fatalf("probeMaxShardSize returned error: %v", err)
+	}
+	if got != succeedsAt {
+		t.Fatalf("got shard size %d, want %d", got, succeedsAt)
+	}
+	if router.reported == 0 {
+		t.Fatal("expected failed probes to be reported to mission control")
+	}
+}
+
+func TestProbeMaxShardSizeFloorsAtMinimum(t *testing.T) {
+	l, _ := newProbeTestLnd(func(amount uint64) error {
+		return errors.New("no route")
+	})
+
+	channel := &lndclient.ChannelInfo{ChannelID: 1}
+	_, err := l.probeMaxShardSize(minProbeShardMsat, route.Vertex{}, channel)
+	if err == nil {
+		t.Fatal("expected an error when no amount down to the minimum probe size routes")
+	}
+}
+
+func TestProbeMaxShardSizeTriesOnceBelowFloor(t *testing.T) {
+	const amountMsat = 5_000_000 // below minProbeShardMsat
+
+	attempts := 0
+	l, _ := newProbeTestLnd(func(amount uint64) error {
+		attempts++
+		if amount != amountMsat {
+			t.Fatalf("probed amount %d, want %d", amount, amountMsat)
+		}
+		return nil
+	})
+
+	channel := &lndclient.ChannelInfo{ChannelID: 1}
+	got, err := l.probeMaxShardSize(amountMsat, route.Vertex{}, channel)
+	if err != nil {
+		t.Fatalf("probeMaxShardSize returned error: %v", err)
+	}
+	if got != amountMsat {
+		t.Fatalf("got shard size %d, want %d", got, amountMsat)
+	}
+	if attempts != 1 {
+		t.Fatalf("probed %d times, want exactly 1", attempts)
+	}
+}
+
+func TestProbeMaxShardSizeBelowFloorStillFails(t *testing.T) {
+	const amountMsat = 5_000_000 // below minProbeShardMsat
+
+	attempts := 0
+	l, _ := newProbeTestLnd(func(amount uint64) error {
+		attempts++
+		return errors.New("no route")
+	})
+
+	channel := &lndclient.ChannelInfo{ChannelID: 1}
+	_, err := l.probeMaxShardSize(amountMsat, route.Vertex{}, channel)
+	if err == nil {
+		t.Fatal("expected an error when the only probe attempt fails")
+	}
+	if attempts != 1 {
+		t.Fatalf("probed %d times, want exactly 1", attempts)
+	}
+}
+
+func TestProbeMaxShardSizeZeroAmountDoesNotHang(t *testing.T) {
+	attempts := 0
+	l, _ := newProbeTestLnd(func(amount uint64) error {
+		attempts++
+		if attempts > 1 {
+			t.Fatal("probed more than once for a zero-amount request")
+		}
+		return errors.New("no route")
+	})
+
+	channel := &lndclient.ChannelInfo{ChannelID: 1}
+	_, err := l.probeMaxShardSize(0, route.Vertex{}, channel)
+	if err == nil {
+		t.Fatal("expected an error when the only probe attempt fails")
+	}
+	if attempts != 1 {
+		t.Fatalf("probed %d times, want exactly 1", attempts)
+	}
+}
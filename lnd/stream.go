@@ -0,0 +1,153 @@
+package lnd
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	streamMinBackoff = 500 * time.Millisecond
+	streamMaxBackoff = 60 * time.Second
+)
+
+// StreamStatus describes the current health of one of the supervised gRPC
+// subscriptions kept alive by Lnd.
+type StreamStatus struct {
+	Name            string
+	Connected       bool
+	LastError       string
+	LastConnectedAt time.Time
+	ReconnectCount  int
+}
+
+// StreamStatus returns a snapshot of the health of every subscription stream
+// currently supervised by Lnd (messages, payments, peer events, and any
+// hold-invoice watches started by WatchHoldInvoice).
+func (l *Lnd) StreamStatus() map[string]StreamStatus {
+	l.streamStatusMu.Lock()
+	defer l.streamStatusMu.Unlock()
+
+	out := make(map[string]StreamStatus, len(l.streamStatus))
+	for k, v := range l.streamStatus {
+		out[k] = *v
+	}
+	return out
+}
+
+func (l *Lnd) setStreamConnected(name string) {
+	l.streamStatusMu.Lock()
+	defer l.streamStatusMu.Unlock()
+
+	s, ok := l.streamStatus[name]
+	if !ok {
+		s = &StreamStatus{Name: name}
+		l.streamStatus[name] = s
+	}
+	s.Connected = true
+	s.LastError = ""
+	s.LastConnectedAt = time.Now()
+}
+
+func (l *Lnd) setStreamError(name string, err error) {
+	l.streamStatusMu.Lock()
+	defer l.streamStatusMu.Unlock()
+
+	s, ok := l.streamStatus[name]
+	if !ok {
+		s = &StreamStatus{Name: name}
+		l.streamStatus[name] = s
+	}
+	s.Connected = false
+	s.LastError = err.Error()
+	s.ReconnectCount++
+}
+
+// superviseStream runs fn in a loop, reconnecting with exponential backoff
+// and jitter whenever it returns an error, until l.ctx is canceled. fn is
+// expected to block until the underlying stream ends.
+func (l *Lnd) superviseStream(name string, fn func(ctx context.Context) error) {
+	backoff := streamMinBackoff
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+		default:
+		}
+
+		err := fn(l.ctx)
+		if err == nil || l.ctx.Err() != nil {
+			return
+		}
+
+		l.setStreamError(name, err)
+		if l.metrics != nil {
+			l.metrics.StreamReconnects.WithLabelValues(name).Inc()
+		}
+		log.Warnf("stream %q disconnected, reconnecting in %s: %v", name, backoff, err)
+
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-time.After(jitter(backoff)):
+		}
+
+		backoff *= 2
+		if backoff > streamMaxBackoff {
+			backoff = streamMaxBackoff
+		}
+	}
+}
+
+// jitter returns d plus or minus up to 20% random variation so that
+// reconnecting goroutines don't all hammer lnd in lockstep.
+func jitter(d time.Duration) time.Duration {
+	spread := float64(d) * 0.2
+	return d + time.Duration(rand.Float64()*2*spread-spread)
+}
+
+// invoiceCheckpoint is persisted to disk so that listenPayments can resume
+// SubscribeInvoices from where it left off across restarts instead of
+// replaying (or missing) settlements.
+type invoiceCheckpoint struct {
+	AddIndex    uint64 `json:"add_index"`
+	SettleIndex uint64 `json:"settle_index"`
+}
+
+func (l *Lnd) loadInvoiceCheckpoint() invoiceCheckpoint {
+	if l.invoiceIndexPath == "" {
+		return invoiceCheckpoint{}
+	}
+	data, err := ioutil.ReadFile(l.invoiceIndexPath)
+	if err != nil {
+		return invoiceCheckpoint{}
+	}
+	var cp invoiceCheckpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		log.Warnf("could not parse invoice checkpoint, starting fresh: %v", err)
+		return invoiceCheckpoint{}
+	}
+	return cp
+}
+
+func (l *Lnd) saveInvoiceCheckpoint(cp invoiceCheckpoint) {
+	if l.invoiceIndexPath == "" {
+		return
+	}
+	data, err := json.Marshal(cp)
+	if err != nil {
+		log.Errorf("could not marshal invoice checkpoint: %v", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(l.invoiceIndexPath), 0755); err != nil {
+		log.Errorf("could not create invoice checkpoint dir: %v", err)
+		return
+	}
+	if err := ioutil.WriteFile(l.invoiceIndexPath, data, 0644); err != nil {
+		log.Errorf("could not persist invoice checkpoint: %v", err)
+	}
+}
@@ -0,0 +1,18 @@
+package lnd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJitterStaysWithinTwentyPercentSpread(t *testing.T) {
+	const d = 10 * time.Second
+	const spread = float64(d) * 0.2
+
+	for i := 0; i < 100; i++ {
+		got := jitter(d)
+		if float64(got) < float64(d)-spread || float64(got) > float64(d)+spread {
+			t.Fatalf("jitter(%s) = %s, outside +/-20%% spread", d, got)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+// Package swaprpc is peerswap's gRPC + REST control plane: initiating
+// swap-in/swap-out, listing active swaps, inspecting history, triggering a
+// rebalance, and reading peer poll state - all macaroon-gated, the same
+// pattern lnd itself uses for lnrpc plus its grpc-gateway REST proxy.
+//
+// swap.proto is the source of truth. `make rpc` regenerates swap.pb.go,
+// swap_grpc.pb.go, swap.pb.gw.go and swagger.json from it (via protoc,
+// protoc-gen-go, protoc-gen-go-grpc, protoc-gen-grpc-gateway and
+// protoc-gen-openapiv2), the same way lnd's own
+// lnrpc package is generated. server.go, gateway.go and macaroons.go are
+// hand-written and build on top of those generated types.
+package swaprpc
@@ -0,0 +1,49 @@
+package swaprpc
+
+import "testing"
+
+// TestPermissionsCoverAllServiceMethods guards against a new RPC being added
+// to SwapServiceServer (or swap.proto) without a matching entry in the
+// macaroon permissions table, which UnaryServerInterceptor treats as an
+// unconditional rejection rather than a missing check.
+func TestPermissionsCoverAllServiceMethods(t *testing.T) {
+	wantMethods := []string{
+		"/swaprpc.SwapService/SwapOut",
+		"/swaprpc.SwapService/SwapIn",
+		"/swaprpc.SwapService/ListSwaps",
+		"/swaprpc.SwapService/SwapInfo",
+		"/swaprpc.SwapService/Rebalance",
+		"/swaprpc.SwapService/ListPeers",
+	}
+
+	for _, method := range wantMethods {
+		ops, ok := permissions[method]
+		if !ok {
+			t.Errorf("permissions table is missing an entry for %s", method)
+			continue
+		}
+		if len(ops) == 0 {
+			t.Errorf("permissions[%s] has no required ops", method)
+		}
+	}
+
+	if len(permissions) != len(wantMethods) {
+		t.Errorf("permissions table has %d entries, want %d - an RPC was added/removed without updating this test", len(permissions), len(wantMethods))
+	}
+}
+
+func TestReadOnlyMethodsDoNotRequireInitiate(t *testing.T) {
+	readOnly := []string{
+		"/swaprpc.SwapService/ListSwaps",
+		"/swaprpc.SwapService/SwapInfo",
+		"/swaprpc.SwapService/ListPeers",
+	}
+
+	for _, method := range readOnly {
+		for _, op := range permissions[method] {
+			if op.Action == "initiate" {
+				t.Errorf("%s requires the initiate action, but is meant to be read-only", method)
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package swaprpc
+
+import (
+	"context"
+
+	"github.com/sputn1ck/peerswap/poll"
+)
+
+// LightningNode is the subset of the lnd/cln backend interface the control
+// plane needs: sending a rebalance payment and reading peer connectivity.
+// Both pkg/lnd's Lnd and pkg/cln's Cln already satisfy this.
+type LightningNode interface {
+	RebalancePayment(payreq string, channelId string, maxFeePpm uint64) (preimage string, err error)
+	GetPeers() []string
+}
+
+// SwapManager is implemented by the daemon's swap service and drives the
+// actual swap-in/swap-out state machines. The control plane only dispatches
+// to it and reports back state.
+type SwapManager interface {
+	SwapOut(channelId string, amountSat uint64, asset string) (swapId string, err error)
+	SwapIn(channelId string, amountSat uint64, asset string) (swapId string, err error)
+	ListSwaps() []Swap
+	GetSwap(swapId string) (Swap, error)
+}
+
+// Swap is the control-plane view of a swap's state, independent of the
+// concrete swap-in/swap-out state machine implementation.
+type Swap struct {
+	SwapId    string
+	State     string
+	ChannelId string
+	AmountSat uint64
+	Asset     string
+}
+
+// server implements the generated SwapServiceServer against a LightningNode,
+// a SwapManager and the poll.Service, so it works unchanged against either
+// the lnd or the cln backend.
+type server struct {
+	UnimplementedSwapServiceServer
+
+	lightning LightningNode
+	swaps     SwapManager
+	poll      *poll.Service
+}
+
+// NewServer wires the SwapService implementation to the daemon's backend,
+// swap manager, and poll service. Every method below is expected to be
+// reached only after the macaroon interceptor (see macaroons.go) has
+// verified the caveat named in swap.proto for that RPC.
+func NewServer(lightning LightningNode, swaps SwapManager, pollService *poll.Service) SwapServiceServer {
+	return &server{
+		lightning: lightning,
+		swaps:     swaps,
+		poll:      pollService,
+	}
+}
+
+func (s *server) SwapOut(ctx context.Context, req *SwapOutRequest) (*SwapResponse, error) {
+	swapId, err := s.swaps.SwapOut(req.ChannelId, req.AmountSat, req.Asset)
+	if err != nil {
+		return nil, err
+	}
+	return s.SwapInfo(ctx, &SwapInfoRequest{SwapId: swapId})
+}
+
+func (s *server) SwapIn(ctx context.Context, req *SwapInRequest) (*SwapResponse, error) {
+	swapId, err := s.swaps.SwapIn(req.ChannelId, req.AmountSat, req.Asset)
+	if err != nil {
+		return nil, err
+	}
+	return s.SwapInfo(ctx, &SwapInfoRequest{SwapId: swapId})
+}
+
+func (s *server) ListSwaps(ctx context.Context, req *ListSwapsRequest) (*ListSwapsResponse, error) {
+	swaps := s.swaps.ListSwaps()
+	res := &ListSwapsResponse{Swaps: make([]*SwapResponse, 0, len(swaps))}
+	for _, sw := range swaps {
+		res.Swaps = append(res.Swaps, toSwapResponse(sw))
+	}
+	return res, nil
+}
+
+func (s *server) SwapInfo(ctx context.Context, req *SwapInfoRequest) (*SwapResponse, error) {
+	sw, err := s.swaps.GetSwap(req.SwapId)
+	if err != nil {
+		return nil, err
+	}
+	return toSwapResponse(sw), nil
+}
+
+func (s *server) Rebalance(ctx context.Context, req *RebalanceRequest) (*RebalanceResponse, error) {
+	preimage, err := s.lightning.RebalancePayment(req.Payreq, req.ChannelId, req.MaxFeePpm)
+	if err != nil {
+		return nil, err
+	}
+	return &RebalanceResponse{Preimage: preimage}, nil
+}
+
+func (s *server) ListPeers(ctx context.Context, req *ListPeersRequest) (*ListPeersResponse, error) {
+	res := &ListPeersResponse{}
+	for _, peerId := range s.lightning.GetPeers() {
+		state := PeerPollState{PeerId: peerId, Online: true}
+		if s.poll != nil {
+			if lastSeen, ok := s.poll.LastSeen(peerId); ok {
+				state.LastSeenUnix = lastSeen.Unix()
+			}
+		}
+		res.Peers = append(res.Peers, &state)
+	}
+	return res, nil
+}
+
+func toSwapResponse(sw Swap) *SwapResponse {
+	return &SwapResponse{
+		SwapId:    sw.SwapId,
+		State:     sw.State,
+		ChannelId: sw.ChannelId,
+		AmountSat: sw.AmountSat,
+		Asset:     sw.Asset,
+	}
+}